@@ -0,0 +1,163 @@
+package goEmail
+
+import (
+	"encoding/base64"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+)
+
+// attachment represents a single file attached to or embedded within an
+// email. open is called at write time rather than when the attachment is
+// added, so an attachment's contents are read and streamed through the
+// message as it is written rather than buffered in full beforehand.
+type attachment struct {
+	filename    string
+	contentType string
+	contentID   string
+	open        func() (io.Reader, error)
+}
+
+// AttachFile attaches the file at path to the email, detecting its
+// Content-Type from the file extension. The file is opened lazily, when the
+// email is written, rather than now.
+func (email *Email) AttachFile(path string) error {
+	email.attachments = append(email.attachments, attachment{
+		filename:    filepath.Base(path),
+		contentType: orOctetStream(mime.TypeByExtension(filepath.Ext(path))),
+		open:        func() (io.Reader, error) { return os.Open(path) },
+	})
+	return nil
+}
+
+// Attach arranges for the contents of r to be attached to the email as a
+// downloadable file with the given filename and Content-Type. r is not read
+// until the email is written. If contentType is empty,
+// "application/octet-stream" is used.
+func (email *Email) Attach(filename string, r io.Reader, contentType string) error {
+	email.attachments = append(email.attachments, attachment{
+		filename:    filename,
+		contentType: orOctetStream(contentType),
+		open:        func() (io.Reader, error) { return r, nil },
+	})
+	return nil
+}
+
+// Embed arranges for the contents of r to be attached to the email as
+// inline content addressable from an HTML body via "cid:<contentID>". r is
+// not read until the email is written. If contentType is empty,
+// "application/octet-stream" is used.
+func (email *Email) Embed(contentID, filename string, r io.Reader, contentType string) error {
+	email.embeds = append(email.embeds, attachment{
+		filename:    filename,
+		contentType: orOctetStream(contentType),
+		contentID:   contentID,
+		open:        func() (io.Reader, error) { return r, nil },
+	})
+	return nil
+}
+
+// orOctetStream returns contentType, falling back to
+// "application/octet-stream" when it is empty.
+func orOctetStream(contentType string) string {
+	if contentType == "" {
+		return "application/octet-stream"
+	}
+	return contentType
+}
+
+// addPart writes the attachment as a single MIME body part within the
+// multipart section identified by boundary, base64-encoding its contents in
+// 76-column lines per RFC 2045 as they are read from a.open, rather than
+// buffering the whole attachment first. disposition is either "attachment"
+// or "inline".
+func (a attachment) addPart(fEmail *formattedEmail, boundary, disposition string) {
+	r, err := a.open()
+	if err != nil {
+		if fEmail.err == nil {
+			fEmail.err = err
+		}
+		return
+	}
+	if closer, ok := r.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	fEmail.addPartBoundary(boundary)
+	fEmail.addHeader("Content-Type", a.contentType)
+	fEmail.addHeader("Content-Transfer-Encoding", "base64")
+	if a.contentID != "" {
+		fEmail.addHeader("Content-ID", "<"+a.contentID+">")
+	}
+	fEmail.addHeader("Content-Disposition", disposition+`; filename="`+a.filename+`"`)
+	io.WriteString(fEmail, "\r\n")
+
+	lw := newBase64LineWriter(fEmail)
+	if _, err := io.Copy(lw, r); err != nil {
+		if fEmail.err == nil {
+			fEmail.err = err
+		}
+		return
+	}
+	if err := lw.Close(); err != nil && fEmail.err == nil {
+		fEmail.err = err
+	}
+}
+
+// base64LineWriter base64-encodes bytes written to it and writes the
+// encoded result to w, wrapped at 76 columns as required of encoded MIME
+// body parts. Close must be called to flush the final partial line.
+type base64LineWriter struct {
+	enc     io.WriteCloser
+	w       io.Writer
+	lineBuf []byte
+}
+
+// newBase64LineWriter returns a base64LineWriter that streams base64-encoded,
+// 76-column-wrapped output to w.
+func newBase64LineWriter(w io.Writer) *base64LineWriter {
+	lw := &base64LineWriter{w: w}
+	lw.enc = base64.NewEncoder(base64.StdEncoding, lineSplitter{lw})
+	return lw
+}
+
+// Write base64-encodes p, writing the result through to w in 76-column
+// lines as lineSplitter receives it.
+func (lw *base64LineWriter) Write(p []byte) (int, error) {
+	return lw.enc.Write(p)
+}
+
+// Close flushes the base64 encoder and any remaining partial line.
+func (lw *base64LineWriter) Close() error {
+	if err := lw.enc.Close(); err != nil {
+		return err
+	}
+	if len(lw.lineBuf) > 0 {
+		_, err := lw.w.Write(lw.lineBuf)
+		lw.lineBuf = nil
+		return err
+	}
+	return nil
+}
+
+// lineSplitter receives raw base64 output from a base64LineWriter's encoder
+// and re-chunks it into 76-column lines terminated by "\r\n".
+type lineSplitter struct {
+	lw *base64LineWriter
+}
+
+func (s lineSplitter) Write(p []byte) (int, error) {
+	n := len(p)
+	s.lw.lineBuf = append(s.lw.lineBuf, p...)
+	for len(s.lw.lineBuf) >= 76 {
+		if _, err := s.lw.w.Write(s.lw.lineBuf[:76]); err != nil {
+			return n, err
+		}
+		if _, err := io.WriteString(s.lw.w, "\r\n"); err != nil {
+			return n, err
+		}
+		s.lw.lineBuf = s.lw.lineBuf[76:]
+	}
+	return n, nil
+}
@@ -0,0 +1,59 @@
+package goEmail
+
+import (
+	"bytes"
+	"errors"
+	"net/smtp"
+	"strings"
+)
+
+// loginAuth implements the LOGIN SASL mechanism, used by servers such as
+// Outlook and Office365 that do not advertise PLAIN or CRAM-MD5.
+type loginAuth struct {
+	username, password string
+	host               string
+}
+
+// LoginAuth returns an smtp.Auth that implements the LOGIN authentication
+// mechanism. It answers the server's "Username:" and "Password:" prompts
+// (matched case-insensitively) with username and password, and refuses to
+// send credentials over a connection that is neither TLS-protected nor
+// explicitly advertising LOGIN support.
+func LoginAuth(username, password, host string) smtp.Auth {
+	return &loginAuth{username, password, host}
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if server.Name != a.host {
+		return "", nil, errors.New("goEmail: wrong host name")
+	}
+	if !server.TLS && !serverAdvertises(server, "LOGIN") {
+		return "", nil, errors.New("goEmail: refusing to send LOGIN credentials over an unencrypted connection")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch {
+	case bytes.EqualFold(fromServer, []byte("Username:")):
+		return []byte(a.username), nil
+	case bytes.EqualFold(fromServer, []byte("Password:")):
+		return []byte(a.password), nil
+	default:
+		return nil, errors.New("goEmail: unexpected server challenge during LOGIN auth: " + string(fromServer))
+	}
+}
+
+// serverAdvertises reports whether server.Auth lists mechanism, ignoring
+// case.
+func serverAdvertises(server *smtp.ServerInfo, mechanism string) bool {
+	for _, m := range server.Auth {
+		if strings.EqualFold(m, mechanism) {
+			return true
+		}
+	}
+	return false
+}
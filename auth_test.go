@@ -0,0 +1,94 @@
+package goEmail
+
+import (
+	"net/smtp"
+	"testing"
+)
+
+func TestLoginAuthStartTLS(t *testing.T) {
+	a := LoginAuth("user", "pass", "smtp.example.com")
+	server := &smtp.ServerInfo{Name: "smtp.example.com", TLS: true}
+
+	mech, resp, err := a.Start(server)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if mech != "LOGIN" {
+		t.Errorf("mechanism = %q, want LOGIN", mech)
+	}
+	if resp != nil {
+		t.Errorf("initial response = %q, want nil", resp)
+	}
+}
+
+func TestLoginAuthStartAdvertisedWithoutTLS(t *testing.T) {
+	a := LoginAuth("user", "pass", "smtp.example.com")
+	server := &smtp.ServerInfo{Name: "smtp.example.com", TLS: false, Auth: []string{"login"}}
+
+	if _, _, err := a.Start(server); err != nil {
+		t.Errorf("Start: %v, want no error when LOGIN is advertised", err)
+	}
+}
+
+func TestLoginAuthStartRefusesUnencryptedUnadvertised(t *testing.T) {
+	a := LoginAuth("user", "pass", "smtp.example.com")
+	server := &smtp.ServerInfo{Name: "smtp.example.com", TLS: false, Auth: []string{"PLAIN"}}
+
+	if _, _, err := a.Start(server); err == nil {
+		t.Error("Start: got no error, want a refusal to send LOGIN credentials unencrypted")
+	}
+}
+
+func TestLoginAuthStartWrongHost(t *testing.T) {
+	a := LoginAuth("user", "pass", "smtp.example.com")
+	server := &smtp.ServerInfo{Name: "smtp.evil.com", TLS: true}
+
+	if _, _, err := a.Start(server); err == nil {
+		t.Error("Start: got no error, want an error for a mismatched host")
+	}
+}
+
+func TestLoginAuthNextChallenges(t *testing.T) {
+	a := LoginAuth("user", "pass", "smtp.example.com")
+
+	tests := []struct {
+		challenge string
+		want      string
+	}{
+		{"Username:", "user"},
+		{"username:", "user"},
+		{"USERNAME:", "user"},
+		{"Password:", "pass"},
+		{"password:", "pass"},
+	}
+	for _, tt := range tests {
+		got, err := a.Next([]byte(tt.challenge), true)
+		if err != nil {
+			t.Errorf("Next(%q, true): %v", tt.challenge, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("Next(%q, true) = %q, want %q", tt.challenge, got, tt.want)
+		}
+	}
+}
+
+func TestLoginAuthNextUnexpectedChallenge(t *testing.T) {
+	a := LoginAuth("user", "pass", "smtp.example.com")
+
+	if _, err := a.Next([]byte("Favorite color:"), true); err == nil {
+		t.Error("Next: got no error, want one for an unrecognized challenge")
+	}
+}
+
+func TestLoginAuthNextNoMore(t *testing.T) {
+	a := LoginAuth("user", "pass", "smtp.example.com")
+
+	got, err := a.Next(nil, false)
+	if err != nil {
+		t.Fatalf("Next(nil, false): %v", err)
+	}
+	if got != nil {
+		t.Errorf("Next(nil, false) = %q, want nil", got)
+	}
+}
@@ -0,0 +1,141 @@
+package goEmail
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+// Dialer sends emails through an SMTP submission server, negotiating TLS
+// and authentication on the caller's behalf so users don't have to
+// construct an smtp.Auth themselves.
+type Dialer struct {
+	Host, Username, Password string
+	Port                     int
+	TLSConfig                *tls.Config
+
+	// SSL indicates the server expects implicit TLS (e.g. port 465) rather
+	// than STARTTLS.
+	SSL bool
+}
+
+// DialAndSend connects to the Dialer's server, authenticates if credentials
+// are set, and sends email. It upgrades the connection with STARTTLS when
+// the server advertises it and SSL is false.
+func (d *Dialer) DialAndSend(email *Email) error {
+	return d.sendRaw(email.From, email.To, func(w io.Writer) error {
+		_, err := email.WriteTo(w)
+		return err
+	})
+}
+
+// sendRaw dials the server, negotiates TLS and authentication, and hands
+// the DATA stream to write. It is shared by DialAndSend and SMTPSender.
+func (d *Dialer) sendRaw(from string, to []string, write func(io.Writer) error) error {
+	conn, err := d.dial()
+	if err != nil {
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, d.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if !d.SSL {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(d.tlsConfig()); err != nil {
+				return err
+			}
+		}
+	}
+
+	if d.Username != "" {
+		auth, err := d.auth(client)
+		if err != nil {
+			return err
+		}
+		if auth != nil {
+			if err := client.Auth(auth); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err := client.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if err := write(w); err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// SMTPSender adapts a Dialer to the Sender interface so it can be used
+// interchangeably with MailgunSender and DevSender.
+type SMTPSender struct {
+	Dialer
+}
+
+// Send implements Sender.
+func (s *SMTPSender) Send(from string, to []string, msg []byte) error {
+	return s.sendRaw(from, to, func(w io.Writer) error {
+		_, err := w.Write(msg)
+		return err
+	})
+}
+
+// dial opens the underlying connection, using implicit TLS when d.SSL is
+// set.
+func (d *Dialer) dial() (net.Conn, error) {
+	addr := fmt.Sprintf("%s:%d", d.Host, d.Port)
+	if d.SSL {
+		return tls.Dial("tcp", addr, d.tlsConfig())
+	}
+	return net.Dial("tcp", addr)
+}
+
+// tlsConfig returns d.TLSConfig, or a default config naming d.Host as the
+// server to verify against.
+func (d *Dialer) tlsConfig() *tls.Config {
+	if d.TLSConfig != nil {
+		return d.TLSConfig
+	}
+	return &tls.Config{ServerName: d.Host}
+}
+
+// auth chooses an smtp.Auth appropriate for the mechanisms client's server
+// advertised, preferring CRAM-MD5, then LOGIN for servers that don't also
+// advertise PLAIN, and falling back to PLAIN.
+func (d *Dialer) auth(client *smtp.Client) (smtp.Auth, error) {
+	ok, auths := client.Extension("AUTH")
+	if !ok {
+		return nil, nil
+	}
+	switch {
+	case strings.Contains(auths, "CRAM-MD5"):
+		return smtp.CRAMMD5Auth(d.Username, d.Password), nil
+	case strings.Contains(auths, "LOGIN") && !strings.Contains(auths, "PLAIN"):
+		return LoginAuth(d.Username, d.Password, d.Host), nil
+	default:
+		return smtp.PlainAuth("", d.Username, d.Password, d.Host), nil
+	}
+}
@@ -4,9 +4,9 @@ package goEmail
 
 import (
 	"bytes"
-	"crypto/sha1"
 	"fmt"
 	"github.com/stephens2424/quotedPrintable"
+	"io"
 	"net/smtp"
 	"strings"
 	"time"
@@ -17,7 +17,23 @@ type Email struct {
 	To, Cc, Bcc   []string
 	From, Subject string
 	emailBodies   []emailBody
+	attachments   []attachment
+	embeds        []attachment
 	encoder       TransferEncoder
+	headerEncoder HeaderEncoder
+
+	messageIDDomain string
+
+	// Clock overrides the source of the current time, used for the Date
+	// header and for Message-ID generation. It defaults to time.Now when
+	// nil; tests can set it for deterministic output.
+	Clock func() time.Time
+}
+
+// SetHeaderEncoder overrides the charset used to RFC 2047-encode non-ASCII
+// header values. The default is UTF-8.
+func (email *Email) SetHeaderEncoder(enc HeaderEncoder) {
+	email.headerEncoder = enc
 }
 
 // Creates a new email with the default transfer encoder (quoted printable).
@@ -39,15 +55,6 @@ type TransferEncoder interface {
 	TransferEncodingType() string
 }
 
-// FormatMailbox accepts an email address and a name and formats
-// a mailbox entry useful in email headers.
-func FormatMailbox(address, name string) string {
-	if name == "" {
-		return address
-	}
-	return name + " <" + address + ">"
-}
-
 // Adds a recipient to the email
 func (email *Email) AddRecipient(mailbox string) {
 	email.To = append(email.To, mailbox)
@@ -82,104 +89,170 @@ func (email *Email) AddTextBody(body string) {
 	email.AddBody("text/plain; charset=utf-8", body)
 }
 
-// MessageID constructs the message ID of an email. This implementation
-// defines the message ID as the sha1 digest of the entire email object.
-func (e *Email) MessageID() string {
-	hasher := sha1.New()
-	hasher.Write([]byte(fmt.Sprintf("%+v", e)))
-	return fmt.Sprintf("%x", hasher.Sum(nil))
-}
-
-// formattedEmail encapsulates a format. It is used internally to manage
+// formattedEmail writes a formatted email directly to an underlying
+// io.Writer as each part is produced, so memory use does not grow with the
+// size of the message or its attachments. It is used internally to manage
 // multiple mimetypes in an email body.
 type formattedEmail struct {
-	buffer   bytes.Buffer
-	boundary string
-	encoder  TransferEncoder
+	w             io.Writer
+	n             int64
+	err           error
+	encoder       TransferEncoder
+	headerEncoder HeaderEncoder
 }
 
-// String returns the formatted email's internal buffer as a string.
-func (e *formattedEmail) String() string {
-	return e.buffer.String()
+// Write implements io.Writer, tracking the running byte count and the
+// first error encountered. Once err is set, further writes are no-ops, so
+// callers need only check fEmail.err once at the end.
+func (e *formattedEmail) Write(p []byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	n, err := e.w.Write(p)
+	e.n += int64(n)
+	if err != nil {
+		e.err = err
+	}
+	return n, err
 }
 
-// addHeader adds a header to a formatted email.
+// addHeader adds a header to a formatted email. value is folded as-is; use
+// addEncodedHeader for unstructured text that may contain non-ASCII
+// characters, such as Subject.
 func (e *formattedEmail) addHeader(field, value string) {
 	if value != "" {
-		e.buffer.WriteString(foldString(78, fmt.Sprintf("%s: ", field), value))
+		io.WriteString(e, foldHeader(78, fmt.Sprintf("%s: ", field), value))
 	}
 }
 
+// addEncodedHeader is like addHeader, but RFC 2047-encodes value first when
+// it contains non-ASCII characters.
+func (e *formattedEmail) addEncodedHeader(field, value string) {
+	e.addHeader(field, e.headerEncoder.EncodeWord(value))
+}
+
+// addPartBoundary writes the opening boundary line that begins a new body
+// part within the multipart section identified by boundary.
+func (fEmail *formattedEmail) addPartBoundary(boundary string) {
+	io.WriteString(fEmail, "\r\n--"+boundary+"\r\n")
+}
+
+// closeBoundary writes the closing boundary line that terminates the
+// multipart section identified by boundary.
+func (fEmail *formattedEmail) closeBoundary(boundary string) {
+	io.WriteString(fEmail, "\r\n--"+boundary+"--\r\n")
+}
+
 // addBody adds a body segment to a formatted email, including the
 // necessary headers for the mimetype.
-func (fEmail *formattedEmail) addBody(emailBody emailBody) {
-	fEmail.buffer.WriteString(fEmail.boundary + "\r\n")
+func (fEmail *formattedEmail) addBody(boundary string, emailBody emailBody) {
+	fEmail.addPartBoundary(boundary)
 	fEmail.addHeader("Content-Type", emailBody.mimeType)
 	fEmail.addHeader("Content-Transfer-Encoding", fEmail.encoder.TransferEncodingType())
-	fEmail.buffer.WriteString("\r\n")
-
-	encoded := fEmail.encoder.Encode([]byte(emailBody.bodyText))
-	fEmail.buffer.Write(encoded)
-	fEmail.buffer.WriteString("\r\n")
-}
-
-// foldString returns a string, folded by "\r\n" where it
-// overlaps a maximum length.
-func foldString(maxLength int, prefix, s string) string {
-	var foldedBuffer bytes.Buffer
-	lineBuffer := bytes.NewBufferString(prefix)
-	lineLength := lineBuffer.Len()
-
-	for _, word := range strings.Split(s, " ") {
-		wordLength := len(word)
-		if wordLength+lineLength+1 <= maxLength {
-			lineBuffer.WriteString(word)
-			lineBuffer.WriteString(" ")
-			lineLength += wordLength + 1
-		} else {
-			foldedBuffer.Write(lineBuffer.Bytes())
-			foldedBuffer.WriteString("\r\n ")
-			lineBuffer.Reset()
-			lineBuffer.WriteString(word)
-			lineBuffer.WriteString(" ")
-			lineLength = wordLength + 1
-		}
+	io.WriteString(fEmail, "\r\n")
+	fEmail.Write(fEmail.encoder.Encode([]byte(emailBody.bodyText)))
+}
+
+// writeBodies writes each of bodies as a part of the multipart/alternative
+// section identified by boundary.
+func (fEmail *formattedEmail) writeBodies(boundary string, bodies []emailBody) {
+	for _, body := range bodies {
+		fEmail.addBody(boundary, body)
+	}
+}
+
+// writeEmbeds writes each of embeds as an inline part of the
+// multipart/related section identified by boundary.
+func (fEmail *formattedEmail) writeEmbeds(boundary string, embeds []attachment) {
+	for _, embed := range embeds {
+		embed.addPart(fEmail, boundary, "inline")
+	}
+}
+
+// writeAttachments writes each of attachments as a part of the
+// multipart/mixed section identified by boundary.
+func (fEmail *formattedEmail) writeAttachments(boundary string, attachments []attachment) {
+	for _, a := range attachments {
+		a.addPart(fEmail, boundary, "attachment")
 	}
-	foldedBuffer.Write(lineBuffer.Bytes())
-	foldedBuffer.WriteString("\r\n")
-	return foldedBuffer.String()
 }
 
-// Formats an email for sending, per RFC 5322. This implementation uses the
-// quoted-printable wire encoding for body segments.
-func (email *Email) Format() []byte {
-	fEmail := formattedEmail{encoder: email.encoder}
+// WriteTo formats the email per RFC 5322 and writes it to w, returning the
+// number of bytes written. When the email has attachments or embeds, the
+// multipart/alternative body is nested inside a multipart/related (for
+// embeds) and/or multipart/mixed (for attachments) envelope, per RFC 2046.
+// Because the message is written directly to w, it can be streamed straight
+// to an io.Writer such as the SMTP DATA command rather than buffered
+// wholesale by the caller.
+func (email *Email) WriteTo(w io.Writer) (int64, error) {
+	fEmail := &formattedEmail{w: w, encoder: email.encoder, headerEncoder: email.headerEncoder}
 
-	boundary := fmt.Sprintf("=_%s", email.MessageID())
-	fEmail.boundary = "\r\n--" + boundary
+	altBoundary := "=_alt_" + email.boundaryToken()
+	relBoundary := "=_rel_" + email.boundaryToken()
+	mixBoundary := "=_mix_" + email.boundaryToken()
+
+	hasEmbeds := len(email.embeds) > 0
+	hasAttachments := len(email.attachments) > 0
 
 	fEmail.addHeader("To", strings.Join(email.To, ", "))
 	fEmail.addHeader("Cc", strings.Join(email.Cc, ", "))
 	fEmail.addHeader("Bcc", strings.Join(email.Bcc, ", "))
 	fEmail.addHeader("From", email.From)
-	fEmail.addHeader("Subject", email.Subject)
-	fEmail.addHeader("Date", time.Now().Format(time.RFC1123Z))
-	fEmail.addHeader("Content-Type", fmt.Sprintf("multipart/alternative; boundary=\"%s\"", boundary))
+	fEmail.addEncodedHeader("Subject", email.Subject)
+	fEmail.addHeader("Date", email.now().Format(time.RFC1123Z))
+	fEmail.addHeader("Message-ID", email.MessageID())
 	fEmail.addHeader("MIME-Version", "1.0")
 
-	for _, body := range email.emailBodies {
-		fEmail.addBody(body)
+	switch {
+	case hasAttachments:
+		fEmail.addHeader("Content-Type", fmt.Sprintf("multipart/mixed; boundary=%q", mixBoundary))
+		fEmail.addPartBoundary(mixBoundary)
+		if hasEmbeds {
+			fEmail.addHeader("Content-Type", fmt.Sprintf("multipart/related; boundary=%q", relBoundary))
+			io.WriteString(fEmail, "\r\n")
+			fEmail.addPartBoundary(relBoundary)
+			fEmail.addHeader("Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", altBoundary))
+			io.WriteString(fEmail, "\r\n")
+			fEmail.writeBodies(altBoundary, email.emailBodies)
+			fEmail.closeBoundary(altBoundary)
+			fEmail.writeEmbeds(relBoundary, email.embeds)
+			fEmail.closeBoundary(relBoundary)
+		} else {
+			fEmail.addHeader("Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", altBoundary))
+			io.WriteString(fEmail, "\r\n")
+			fEmail.writeBodies(altBoundary, email.emailBodies)
+			fEmail.closeBoundary(altBoundary)
+		}
+		fEmail.writeAttachments(mixBoundary, email.attachments)
+		fEmail.closeBoundary(mixBoundary)
+	case hasEmbeds:
+		fEmail.addHeader("Content-Type", fmt.Sprintf("multipart/related; boundary=%q", relBoundary))
+		fEmail.addPartBoundary(relBoundary)
+		fEmail.addHeader("Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", altBoundary))
+		io.WriteString(fEmail, "\r\n")
+		fEmail.writeBodies(altBoundary, email.emailBodies)
+		fEmail.closeBoundary(altBoundary)
+		fEmail.writeEmbeds(relBoundary, email.embeds)
+		fEmail.closeBoundary(relBoundary)
+	default:
+		fEmail.addHeader("Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", altBoundary))
+		fEmail.writeBodies(altBoundary, email.emailBodies)
+		fEmail.closeBoundary(altBoundary)
 	}
 
-	return fEmail.buffer.Bytes()
+	return fEmail.n, fEmail.err
 }
 
 // Send the formatted email using the specified server and authentication.
 func (email *Email) Send(addr string, a smtp.Auth) error {
+	var buf bytes.Buffer
+	if _, err := email.WriteTo(&buf); err != nil {
+		return err
+	}
 	return smtp.SendMail(
 		addr,
 		a,
 		email.From,
 		email.To,
-		email.Format())
+		buf.Bytes())
 }
@@ -0,0 +1,102 @@
+package goEmail
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+// countLeafParts walks a (possibly nested) multipart body and returns the
+// number of non-multipart leaf parts it contains.
+func countLeafParts(t *testing.T, contentType string, body io.Reader) int {
+	t.Helper()
+	mt, params, err := mime.ParseMediaType(contentType)
+	if err != nil || !strings.HasPrefix(mt, "multipart/") {
+		t.Fatalf("expected a multipart Content-Type, got %q: %v", contentType, err)
+	}
+
+	mr := multipart.NewReader(body, params["boundary"])
+	n := 0
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("NextPart: %v", err)
+		}
+		if ct := part.Header.Get("Content-Type"); strings.HasPrefix(ct, "multipart/") {
+			n += countLeafParts(t, ct, part)
+		} else {
+			n++
+		}
+		io.Copy(io.Discard, part)
+	}
+	return n
+}
+
+func TestWriteToRoundTrip(t *testing.T) {
+	e := NewEmail()
+	e.Clock = func() time.Time { return time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC) }
+	e.SetMessageIDDomain("example.com")
+	e.From = "sender@example.com"
+	e.To = append(e.To, "recipient@example.com")
+	e.Subject = "Hello"
+	e.AddTextBody("hi there")
+
+	var buf bytes.Buffer
+	if _, err := e.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+
+	msg, err := mail.ReadMessage(&buf)
+	if err != nil {
+		t.Fatalf("net/mail.ReadMessage: %v", err)
+	}
+
+	if got := msg.Header.Get("Date"); got != "Thu, 02 Jan 2020 03:04:05 +0000" {
+		t.Errorf("Date header = %q, want the Clock-provided time", got)
+	}
+	if !strings.HasSuffix(msg.Header.Get("Message-ID"), "@example.com>") {
+		t.Errorf("Message-ID header = %q, want it to end in @example.com>", msg.Header.Get("Message-ID"))
+	}
+
+	if n := countLeafParts(t, msg.Header.Get("Content-Type"), msg.Body); n != 1 {
+		t.Errorf("expected 1 leaf part (the text body), got %d", n)
+	}
+}
+
+func TestWriteToEmbedAndAttachmentRoundTrip(t *testing.T) {
+	e := NewEmail()
+	e.From = "sender@example.com"
+	e.To = append(e.To, "recipient@example.com")
+	e.AddTextBody("hello text")
+	e.AddHtmlBody("<b>hello html</b>")
+	if err := e.Embed("logo", "logo.png", bytes.NewReader([]byte("fake-png")), "image/png"); err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if err := e.Attach("report.pdf", bytes.NewReader([]byte("fake-pdf")), "application/pdf"); err != nil {
+		t.Fatalf("Attach: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := e.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+
+	msg, err := mail.ReadMessage(&buf)
+	if err != nil {
+		t.Fatalf("net/mail.ReadMessage: %v", err)
+	}
+
+	// text + html + embed + attachment must all survive the nested
+	// multipart/mixed > multipart/related > multipart/alternative envelope.
+	if n := countLeafParts(t, msg.Header.Get("Content-Type"), msg.Body); n != 4 {
+		t.Errorf("expected 4 leaf parts (text, html, embed, attachment), got %d", n)
+	}
+}
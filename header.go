@@ -0,0 +1,87 @@
+package goEmail
+
+import (
+	"mime"
+	"strings"
+)
+
+// HeaderEncoder prepares unstructured header text and mailbox display names
+// for the wire, RFC 2047-encoding them when they contain non-ASCII
+// characters. It plays the same role for headers that TransferEncoder plays
+// for bodies. The zero value encodes as UTF-8.
+type HeaderEncoder struct {
+	// Charset is the IANA charset name declared in encoded words, e.g.
+	// "ISO-8859-1". Defaults to "UTF-8".
+	Charset string
+}
+
+// EncodeWord returns s unchanged if it is plain ASCII; otherwise it returns
+// s as one or more RFC 2047 encoded words ("=?charset?Q?...?=" or
+// "...?B?..."), split at the 75-character encoded-word limit, using
+// whichever of Q- or B-encoding is shorter.
+func (h HeaderEncoder) EncodeWord(s string) string {
+	charset := h.Charset
+	if charset == "" {
+		charset = "UTF-8"
+	}
+
+	q := mime.QEncoding.Encode(charset, s)
+	if q == s {
+		return s
+	}
+	if b := mime.BEncoding.Encode(charset, s); len(b) < len(q) {
+		return b
+	}
+	return q
+}
+
+// FormatMailbox accepts an email address and a name and formats a mailbox
+// entry useful in email headers, RFC 2047-encoding the display name when it
+// contains non-ASCII characters and quoting it when it contains `"` or `,`.
+func FormatMailbox(address, name string) string {
+	return HeaderEncoder{}.FormatMailbox(address, name)
+}
+
+// FormatMailbox is like the package-level FormatMailbox, but encodes the
+// display name using h's charset.
+func (h HeaderEncoder) FormatMailbox(address, name string) string {
+	if name == "" {
+		return address
+	}
+	return h.encodeDisplayName(name) + " <" + address + ">"
+}
+
+// encodeDisplayName RFC 2047-encodes name if it needs it, else quotes it if
+// it contains characters special to the mailbox grammar.
+func (h HeaderEncoder) encodeDisplayName(name string) string {
+	if encoded := h.EncodeWord(name); encoded != name {
+		return encoded
+	}
+	if strings.ContainsAny(name, `",`) {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(name) + `"`
+	}
+	return name
+}
+
+// foldHeader returns prefix+s folded by "\r\n " (RFC 5322 §2.2.3 folding
+// whitespace) wherever a line would otherwise exceed maxLength. Folding only
+// ever occurs between whitespace-delimited tokens, so an encoded word -
+// which never contains a space - is always kept intact on one line.
+func foldHeader(maxLength int, prefix, s string) string {
+	var folded strings.Builder
+	line := prefix
+	for _, word := range strings.Split(s, " ") {
+		if line != prefix && len(line)+1+len(word) > maxLength {
+			folded.WriteString(line)
+			folded.WriteString("\r\n")
+			line = " " + word
+		} else if line == prefix {
+			line += word
+		} else {
+			line += " " + word
+		}
+	}
+	folded.WriteString(line)
+	folded.WriteString("\r\n")
+	return folded.String()
+}
@@ -0,0 +1,87 @@
+package goEmail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncodeWordASCII(t *testing.T) {
+	h := HeaderEncoder{}
+	s := "Hello, World! This is plain ASCII text."
+	if got := h.EncodeWord(s); got != s {
+		t.Errorf("EncodeWord(%q) = %q, want it unchanged", s, got)
+	}
+}
+
+func TestEncodeWordNonASCII(t *testing.T) {
+	h := HeaderEncoder{}
+	got := h.EncodeWord("Café Münchhausen")
+	if !strings.HasPrefix(got, "=?UTF-8?") {
+		t.Fatalf("EncodeWord = %q, want an RFC 2047 encoded word", got)
+	}
+	if !strings.HasSuffix(got, "?=") {
+		t.Errorf("EncodeWord = %q, want it to end in ?=", got)
+	}
+}
+
+func TestEncodeWordChoosesShorterEncoding(t *testing.T) {
+	h := HeaderEncoder{}
+	// Mostly-ASCII text with one non-ASCII rune encodes shorter under
+	// Q-encoding than B-encoding, which must re-encode the whole string.
+	s := "This is a long line of plain text with one accented letter: é"
+	got := h.EncodeWord(s)
+	if !strings.Contains(strings.ToUpper(got), "?Q?") {
+		t.Errorf("EncodeWord(%q) = %q, want Q-encoding to be chosen as the shorter option", s, got)
+	}
+}
+
+func TestEncodeWordLongValueSpansMultipleWords(t *testing.T) {
+	h := HeaderEncoder{}
+	s := strings.Repeat("café ", 40)
+	got := h.EncodeWord(s)
+
+	words := strings.Fields(got)
+	if len(words) < 2 {
+		t.Fatalf("EncodeWord of a long non-ASCII value produced %d encoded word(s), want more than one", len(words))
+	}
+	for _, w := range words {
+		if len(w) > 75 {
+			t.Errorf("encoded word %q is %d bytes, want at most 75 per RFC 2047", w, len(w))
+		}
+	}
+}
+
+func TestFoldHeaderShortLineUnfolded(t *testing.T) {
+	got := foldHeader(78, "Subject: ", "short subject")
+	want := "Subject: short subject\r\n"
+	if got != want {
+		t.Errorf("foldHeader = %q, want %q", got, want)
+	}
+}
+
+func TestFoldHeaderLongLineFolds(t *testing.T) {
+	value := strings.Repeat("word ", 20)
+	got := foldHeader(40, "Subject: ", strings.TrimSpace(value))
+
+	for _, line := range strings.Split(strings.TrimSuffix(got, "\r\n"), "\r\n ") {
+		if len(line) > 40 {
+			t.Errorf("folded line %q is %d chars, want at most 40", line, len(line))
+		}
+	}
+	if !strings.Contains(got, "\r\n ") {
+		t.Errorf("foldHeader(%q) = %q, want at least one fold", value, got)
+	}
+}
+
+func TestFoldHeaderKeepsEncodedWordIntact(t *testing.T) {
+	h := HeaderEncoder{}
+	encoded := h.EncodeWord(strings.Repeat("café ", 40))
+	got := foldHeader(40, "Subject: ", encoded)
+
+	for _, line := range strings.Split(strings.TrimSuffix(got, "\r\n"), "\r\n ") {
+		line = strings.TrimPrefix(line, "Subject: ")
+		if strings.HasPrefix(line, "=?") && !strings.HasSuffix(line, "?=") {
+			t.Errorf("folded line %q splits an encoded word mid-way", line)
+		}
+	}
+}
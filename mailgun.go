@@ -0,0 +1,79 @@
+package goEmail
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+)
+
+// MailgunSender delivers email through the Mailgun HTTP API
+// (https://documentation.mailgun.com/en/latest/api-sending.html#sending-a-pre-built-mime-string),
+// POSTing the already-formatted MIME message (including any attachments and
+// embeds) to the domain's messages.mime endpoint.
+//
+// Mailgun's standard /messages endpoint takes from/to/subject/html/text and
+// attachments as separate form fields, but Sender only hands implementations
+// a from, a to list, and an already-formatted msg []byte - it has no way to
+// pass subject/html/text/attachments separately. messages.mime is the
+// Mailgun endpoint built for exactly that shape (a pre-built MIME message),
+// so MailgunSender targets it instead of reconstructing the split-field
+// form from the formatted message. This is an intentional consequence of
+// the Sender interface, not an oversight.
+type MailgunSender struct {
+	Domain string
+	APIKey string
+
+	// BaseURL overrides the Mailgun API root, e.g. for the EU region
+	// (https://api.eu.mailgun.net/v3). Defaults to https://api.mailgun.net/v3.
+	BaseURL string
+}
+
+// Send implements Sender.
+func (s *MailgunSender) Send(from string, to []string, msg []byte) error {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+
+	if err := form.WriteField("from", from); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err := form.WriteField("to", rcpt); err != nil {
+			return err
+		}
+	}
+	part, err := form.CreateFormFile("message", "message.mime")
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(msg); err != nil {
+		return err
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", s.baseURL()+"/"+s.Domain+"/messages.mime", &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.SetBasicAuth("api", s.APIKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("goEmail: mailgun API returned %s", resp.Status)
+	}
+	return nil
+}
+
+func (s *MailgunSender) baseURL() string {
+	if s.BaseURL != "" {
+		return s.BaseURL
+	}
+	return "https://api.mailgun.net/v3"
+}
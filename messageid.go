@@ -0,0 +1,65 @@
+package goEmail
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+)
+
+// base36TokenSpace is the exclusive upper bound passed to rand.Int when
+// generating a base36 token, producing up to 16 base36 digits.
+var base36TokenSpace = new(big.Int).Exp(big.NewInt(36), big.NewInt(16), nil)
+
+// MessageID generates a new RFC 5322 msg-id of the form
+// "<random>.<unix-nanos>@domain", suitable for a Message-ID header. It is
+// not idempotent: each call returns a fresh value, since WriteTo calls it
+// once per message sent.
+func (email *Email) MessageID() string {
+	return fmt.Sprintf("<%s.%d@%s>", randomToken(), email.now().UnixNano(), email.domain())
+}
+
+// SetMessageIDDomain overrides the domain used in generated Message-ID
+// headers. It defaults to the local hostname, as reported by os.Hostname.
+func (email *Email) SetMessageIDDomain(domain string) {
+	email.messageIDDomain = domain
+}
+
+// domain returns the configured Message-ID domain, falling back to the
+// local hostname and then "localhost.localdomain" if that's unavailable.
+func (email *Email) domain() string {
+	if email.messageIDDomain != "" {
+		return email.messageIDDomain
+	}
+	if host, err := os.Hostname(); err == nil && host != "" {
+		return host
+	}
+	return "localhost.localdomain"
+}
+
+// now returns email.Clock(), or time.Now() if no Clock override is set.
+func (email *Email) now() time.Time {
+	if email.Clock != nil {
+		return email.Clock()
+	}
+	return time.Now()
+}
+
+// boundaryToken returns a random token suitable for use in a MIME
+// boundary. It is generated independently from MessageID so that
+// regenerating one never collides with, or is confused for, the other.
+func (email *Email) boundaryToken() string {
+	return randomToken()
+}
+
+// randomToken returns a base36-encoded value read from crypto/rand. If
+// crypto/rand fails, it returns the current Unix time in nanoseconds, in
+// decimal, instead.
+func randomToken() string {
+	n, err := rand.Int(rand.Reader, base36TokenSpace)
+	if err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return n.Text(36)
+}
@@ -0,0 +1,40 @@
+package goEmail
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+// Sender delivers a formatted email message. Implementations let
+// Email.SendVia target different providers (SMTP, an HTTP API, or a local
+// dev sink) via configuration rather than code, the way SMTPSender,
+// MailgunSender, and DevSender do here.
+type Sender interface {
+	Send(from string, to []string, msg []byte) error
+}
+
+// SendVia formats email and delivers it through s.
+func (email *Email) SendVia(s Sender) error {
+	var buf bytes.Buffer
+	if _, err := email.WriteTo(&buf); err != nil {
+		return err
+	}
+	return s.Send(email.From, email.To, buf.Bytes())
+}
+
+// DevSender writes formatted messages to Writer instead of delivering
+// them, for local development. The zero value writes to os.Stdout.
+type DevSender struct {
+	Writer io.Writer
+}
+
+// Send implements Sender.
+func (s *DevSender) Send(from string, to []string, msg []byte) error {
+	w := s.Writer
+	if w == nil {
+		w = os.Stdout
+	}
+	_, err := w.Write(msg)
+	return err
+}
@@ -0,0 +1,180 @@
+package goEmail
+
+import (
+	"net/smtp"
+	"sync"
+)
+
+// Session holds an open, authenticated SMTP connection so many messages can
+// be sent without redialing for each one, as is practical for newsletters
+// and transactional batches.
+type Session struct {
+	dialer Dialer
+	client *smtp.Client
+}
+
+// NewSession dials and authenticates against d's server and returns a
+// Session ready to send messages down the shared connection.
+func NewSession(d Dialer) (*Session, error) {
+	s := &Session{dialer: d}
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// connect (re)dials the session's server, negotiating TLS and
+// authentication exactly as Dialer.DialAndSend does.
+func (s *Session) connect() error {
+	conn, err := s.dialer.dial()
+	if err != nil {
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, s.dialer.Host)
+	if err != nil {
+		return err
+	}
+
+	if !s.dialer.SSL {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(s.dialer.tlsConfig()); err != nil {
+				client.Close()
+				return err
+			}
+		}
+	}
+
+	if s.dialer.Username != "" {
+		auth, err := s.dialer.auth(client)
+		if err != nil {
+			client.Close()
+			return err
+		}
+		if auth != nil {
+			if err := client.Auth(auth); err != nil {
+				client.Close()
+				return err
+			}
+		}
+	}
+
+	if s.client != nil {
+		s.client.Close()
+	}
+	s.client = client
+	return nil
+}
+
+// Send delivers email down the shared connection, issuing RSET beforehand
+// so state from a previous message doesn't leak into this one. If the
+// connection has gone bad, Send reconnects and retries once.
+func (s *Session) Send(email *Email) error {
+	if err := s.client.Reset(); err != nil {
+		if err := s.connect(); err != nil {
+			return err
+		}
+	}
+
+	if err := s.sendOnce(email); err != nil {
+		if connErr := s.connect(); connErr != nil {
+			return err
+		}
+		return s.sendOnce(email)
+	}
+	return nil
+}
+
+func (s *Session) sendOnce(email *Email) error {
+	if err := s.client.Mail(email.From); err != nil {
+		return err
+	}
+	for _, to := range email.To {
+		if err := s.client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := s.client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := email.WriteTo(w); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// SendTemplate renders tmpl for each of its recipients and sends the
+// personalized copies one at a time down the session's shared connection.
+func (s *Session) SendTemplate(tmpl *Template) error {
+	compiled, err := tmpl.compile()
+	if err != nil {
+		return err
+	}
+	for _, r := range tmpl.Recipients {
+		email, err := compiled.render(r)
+		if err != nil {
+			return err
+		}
+		if err := s.Send(email); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close terminates the session's connection cleanly.
+func (s *Session) Close() error {
+	if s.client == nil {
+		return nil
+	}
+	return s.client.Quit()
+}
+
+// SendTemplateConcurrent dials up to concurrency independent Sessions
+// against d and distributes tmpl's recipients across them, so several
+// connections can send to the same server in parallel. A concurrency of
+// less than 1 is treated as 1.
+func SendTemplateConcurrent(d Dialer, tmpl *Template, concurrency int) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	shards := make([]*Template, concurrency)
+	for i := range shards {
+		shard := *tmpl
+		shard.Recipients = nil
+		shards[i] = &shard
+	}
+	for i, r := range tmpl.Recipients {
+		shards[i%concurrency].Recipients = append(shards[i%concurrency].Recipients, r)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, concurrency)
+	for i, shard := range shards {
+		if len(shard.Recipients) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, shard *Template) {
+			defer wg.Done()
+			sess, err := NewSession(d)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			defer sess.Close()
+			errs[i] = sess.SendTemplate(shard)
+		}(i, shard)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package goEmail
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+)
+
+// Recipient is a single destination for a Template send: the mailbox to
+// send to, and the data substituted into the template bodies for it.
+type Recipient struct {
+	Mailbox string
+	Data    interface{}
+}
+
+// Template renders a personalized copy of a shared text/plain and/or
+// text/html body for each Recipient, for use with Session.SendTemplate or
+// SendTemplateConcurrent.
+type Template struct {
+	From, Subject      string
+	TextBody, HTMLBody string
+	Recipients         []Recipient
+}
+
+// compiledTemplate holds the parsed text/template and html/template bodies
+// shared across every Recipient's render.
+type compiledTemplate struct {
+	tmpl *Template
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// compile parses t's bodies once so they can be executed per recipient
+// without re-parsing.
+func (t *Template) compile() (*compiledTemplate, error) {
+	c := &compiledTemplate{tmpl: t}
+
+	if t.TextBody != "" {
+		text, err := texttemplate.New("text").Parse(t.TextBody)
+		if err != nil {
+			return nil, err
+		}
+		c.text = text
+	}
+	if t.HTMLBody != "" {
+		html, err := htmltemplate.New("html").Parse(t.HTMLBody)
+		if err != nil {
+			return nil, err
+		}
+		c.html = html
+	}
+	return c, nil
+}
+
+// render executes the compiled bodies against r.Data and returns a
+// ready-to-send Email addressed to r.Mailbox.
+func (c *compiledTemplate) render(r Recipient) (*Email, error) {
+	email := NewEmail()
+	email.From = c.tmpl.From
+	email.Subject = c.tmpl.Subject
+	email.AddRecipient(r.Mailbox)
+
+	if c.text != nil {
+		var buf bytes.Buffer
+		if err := c.text.Execute(&buf, r.Data); err != nil {
+			return nil, err
+		}
+		email.AddTextBody(buf.String())
+	}
+	if c.html != nil {
+		var buf bytes.Buffer
+		if err := c.html.Execute(&buf, r.Data); err != nil {
+			return nil, err
+		}
+		email.AddHtmlBody(buf.String())
+	}
+	return email, nil
+}